@@ -0,0 +1,213 @@
+// Package cache provides an in-memory view of a repository's bugs, used by
+// both the CLI and the termui so they read and mutate bugs the same way.
+package cache
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/MichaelMure/git-bug/query"
+	"github.com/MichaelMure/git-bug/repository"
+)
+
+// RepoCacher is the read/write interface onto the bug cache of a
+// repository. It is kept separate from RepoCache so callers (the CLI, the
+// termui) don't depend on the concrete implementation.
+type RepoCacher interface {
+	// Repository returns the underlying repository the cache was built on.
+	Repository() repository.Repo
+
+	// NewBug creates and caches a new bug.
+	NewBug(title, message string) (BugCacher, error)
+
+	// ResolveBug fetches a single cached bug by id.
+	ResolveBug(id string) (BugCacher, error)
+
+	// AllBugs returns an excerpt of every cached bug, in cache order.
+	AllBugs() []BugExcerpt
+
+	// QueryBugs returns an excerpt of every cached bug matching q. This
+	// backs both the CLI's `git bug ls` and the termui's filter prompt, so
+	// the two always agree on what a query matches.
+	QueryBugs(q *query.Query) ([]BugExcerpt, error)
+
+	// ValidLabels returns the set of labels already used across the cached
+	// bugs, sorted alphabetically.
+	ValidLabels() []string
+}
+
+// BugCacher is the read/write interface onto a single cached bug.
+type BugCacher interface {
+	Id() string
+	Snapshot() BugSnapshot
+
+	AddComment(message string) error
+	SetTitle(title string) error
+
+	// ChangeLabels adds and removes the given labels on the bug. Labels
+	// already in the requested state are left untouched.
+	ChangeLabels(added []string, removed []string) error
+}
+
+// BugSnapshot is a read-only, denormalized view of a single bug.
+type BugSnapshot struct {
+	Status string
+	Title  string
+	Labels []string
+}
+
+// BugExcerpt is the lightweight, listing-oriented counterpart of
+// BugSnapshot, as used by the bug table.
+type BugExcerpt struct {
+	Id     string
+	Status string
+	Author string
+	Title  string
+	Labels []string
+}
+
+// NewRepoCache loads the bug cache for repo.
+func NewRepoCache(repo repository.Repo) RepoCacher {
+	return &repoCache{repo: repo}
+}
+
+type repoCache struct {
+	repo repository.Repo
+	bugs []*bugCache
+}
+
+func (c *repoCache) Repository() repository.Repo {
+	return c.repo
+}
+
+func (c *repoCache) NewBug(title, message string) (BugCacher, error) {
+	b := &bugCache{
+		id:      fmt.Sprintf("%d", len(c.bugs)+1),
+		status:  "open",
+		title:   title,
+		message: message,
+	}
+
+	c.bugs = append(c.bugs, b)
+
+	return b, nil
+}
+
+func (c *repoCache) ResolveBug(id string) (BugCacher, error) {
+	for _, b := range c.bugs {
+		if b.id == id {
+			return b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown bug %q", id)
+}
+
+func (c *repoCache) AllBugs() []BugExcerpt {
+	excerpts := make([]BugExcerpt, 0, len(c.bugs))
+	for _, b := range c.bugs {
+		excerpts = append(excerpts, b.excerpt())
+	}
+
+	return excerpts
+}
+
+func (c *repoCache) QueryBugs(q *query.Query) ([]BugExcerpt, error) {
+	var matched []BugExcerpt
+
+	for _, b := range c.bugs {
+		e := b.excerpt()
+		if q.Match(e.Status, e.Author, e.Labels, e.Title) {
+			matched = append(matched, e)
+		}
+	}
+
+	return matched, nil
+}
+
+func (c *repoCache) ValidLabels() []string {
+	seen := make(map[string]bool)
+	var labels []string
+
+	for _, b := range c.bugs {
+		for _, label := range b.labels {
+			if !seen[label] {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+
+	sort.Strings(labels)
+
+	return labels
+}
+
+type bugCache struct {
+	id      string
+	status  string
+	author  string
+	title   string
+	message string
+	labels  []string
+}
+
+func (b *bugCache) Id() string {
+	return b.id
+}
+
+func (b *bugCache) excerpt() BugExcerpt {
+	return BugExcerpt{
+		Id:     b.id,
+		Status: b.status,
+		Author: b.author,
+		Title:  b.title,
+		Labels: append([]string{}, b.labels...),
+	}
+}
+
+func (b *bugCache) Snapshot() BugSnapshot {
+	return BugSnapshot{
+		Status: b.status,
+		Title:  b.title,
+		Labels: append([]string{}, b.labels...),
+	}
+}
+
+func (b *bugCache) AddComment(message string) error {
+	return nil
+}
+
+func (b *bugCache) SetTitle(title string) error {
+	b.title = title
+	return nil
+}
+
+func (b *bugCache) ChangeLabels(added []string, removed []string) error {
+	remove := make(map[string]bool, len(removed))
+	for _, label := range removed {
+		remove[label] = true
+	}
+
+	kept := b.labels[:0]
+	for _, label := range b.labels {
+		if !remove[label] {
+			kept = append(kept, label)
+		}
+	}
+	b.labels = kept
+
+	has := make(map[string]bool, len(b.labels))
+	for _, label := range b.labels {
+		has[label] = true
+	}
+
+	for _, label := range added {
+		if !has[label] {
+			b.labels = append(b.labels, label)
+			has[label] = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/MichaelMure/git-bug/query"
+)
+
+func newTestCache(t *testing.T) *repoCache {
+	t.Helper()
+	return &repoCache{}
+}
+
+func TestQueryBugsEmptyMatchesEverything(t *testing.T) {
+	c := newTestCache(t)
+	b, err := c.NewBug("a title", "a message")
+	if err != nil {
+		t.Fatalf("NewBug: %v", err)
+	}
+	if err := b.ChangeLabels([]string{"bug"}, nil); err != nil {
+		t.Fatalf("ChangeLabels: %v", err)
+	}
+
+	q, _ := query.Parse("")
+	got, err := c.QueryBugs(q)
+	if err != nil {
+		t.Fatalf("QueryBugs: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("QueryBugs() returned %d bugs, want 1", len(got))
+	}
+}
+
+func TestQueryBugsFiltersByLabel(t *testing.T) {
+	c := newTestCache(t)
+
+	bugged, _ := c.NewBug("bugged", "")
+	bugged.ChangeLabels([]string{"bug"}, nil)
+
+	c.NewBug("clean", "")
+
+	q, _ := query.Parse("label:bug")
+	got, err := c.QueryBugs(q)
+	if err != nil {
+		t.Fatalf("QueryBugs: %v", err)
+	}
+	if len(got) != 1 || got[0].Title != "bugged" {
+		t.Fatalf("QueryBugs(label:bug) = %v, want only the \"bugged\" bug", got)
+	}
+}
+
+func TestValidLabelsIsSortedAndDeduplicated(t *testing.T) {
+	c := newTestCache(t)
+
+	b1, _ := c.NewBug("one", "")
+	b1.ChangeLabels([]string{"bug", "wontfix"}, nil)
+
+	b2, _ := c.NewBug("two", "")
+	b2.ChangeLabels([]string{"bug"}, nil)
+
+	got := c.ValidLabels()
+	want := []string{"bug", "wontfix"}
+
+	if len(got) != len(want) {
+		t.Fatalf("ValidLabels() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ValidLabels() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestChangeLabelsAddRemoveOverlap(t *testing.T) {
+	cases := []struct {
+		name    string
+		initial []string
+		added   []string
+		removed []string
+		want    []string
+	}{
+		{
+			name:    "duplicate add is a no-op",
+			initial: []string{"bug"},
+			added:   []string{"bug"},
+			want:    []string{"bug"},
+		},
+		{
+			name:    "remove not present is a no-op",
+			initial: []string{"bug"},
+			removed: []string{"wontfix"},
+			want:    []string{"bug"},
+		},
+		{
+			// Removes are applied before adds, so a label present in both
+			// lists ends up added.
+			name:    "add wins over remove for the same label",
+			initial: []string{"bug"},
+			added:   []string{"bug"},
+			removed: []string{"bug"},
+			want:    []string{"bug"},
+		},
+		{
+			name:    "add and remove combine",
+			initial: []string{"bug"},
+			added:   []string{"enhancement"},
+			removed: []string{"bug"},
+			want:    []string{"enhancement"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bugCache{labels: append([]string{}, tc.initial...)}
+
+			if err := b.ChangeLabels(tc.added, tc.removed); err != nil {
+				t.Fatalf("ChangeLabels: %v", err)
+			}
+
+			if len(b.labels) != len(tc.want) {
+				t.Fatalf("labels = %v, want %v", b.labels, tc.want)
+			}
+			for i := range tc.want {
+				if b.labels[i] != tc.want[i] {
+					t.Fatalf("labels = %v, want %v", b.labels, tc.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package query implements the small filtering language shared by the CLI's
+// `git bug ls` and the termui's bug table filter prompt, so the two always
+// agree on what a given query matches.
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Query is a parsed filter: a status, an author substring, a label and a
+// set of free-text words that must all appear in the title.
+type Query struct {
+	Status string
+	Author string
+	Label  string
+	Title  []string
+}
+
+// Usage is a short, human readable description of the query language,
+// displayed wherever a query is typed interactively.
+func Usage() string {
+	return "status:open|closed, author:<name>, label:<name>, free text"
+}
+
+// Parse turns a raw query string into a Query. An empty string parses into
+// a Query that matches everything.
+func Parse(raw string) (*Query, error) {
+	q := &Query{}
+
+	for _, field := range strings.Fields(raw) {
+		switch {
+		case strings.HasPrefix(field, "status:"):
+			status := strings.TrimPrefix(field, "status:")
+			if status != "open" && status != "closed" {
+				return nil, fmt.Errorf("invalid status %q, expected \"open\" or \"closed\"", status)
+			}
+			q.Status = status
+
+		case strings.HasPrefix(field, "author:"):
+			q.Author = strings.TrimPrefix(field, "author:")
+
+		case strings.HasPrefix(field, "label:"):
+			q.Label = strings.TrimPrefix(field, "label:")
+
+		default:
+			q.Title = append(q.Title, field)
+		}
+	}
+
+	return q, nil
+}
+
+// String rebuilds a normalized, displayable form of the query.
+func (q *Query) String() string {
+	var parts []string
+
+	if q.Status != "" {
+		parts = append(parts, "status:"+q.Status)
+	}
+	if q.Author != "" {
+		parts = append(parts, "author:"+q.Author)
+	}
+	if q.Label != "" {
+		parts = append(parts, "label:"+q.Label)
+	}
+
+	parts = append(parts, q.Title...)
+
+	return strings.Join(parts, " ")
+}
+
+// Match reports whether a bug described by the given status, author,
+// labels and title satisfies the query.
+func (q *Query) Match(status, author string, labels []string, title string) bool {
+	if q.Status != "" && q.Status != status {
+		return false
+	}
+
+	if q.Author != "" && !strings.Contains(strings.ToLower(author), strings.ToLower(q.Author)) {
+		return false
+	}
+
+	if q.Label != "" {
+		found := false
+		for _, label := range labels {
+			if strings.EqualFold(label, q.Label) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, word := range q.Title {
+		if !strings.Contains(strings.ToLower(title), strings.ToLower(word)) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,118 @@
+package query
+
+import "testing"
+
+func TestParseEmpty(t *testing.T) {
+	q, err := Parse("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !q.Match("open", "anyone", []string{"anything"}, "any title") {
+		t.Error("empty query should match everything")
+	}
+}
+
+func TestParseInvalidStatus(t *testing.T) {
+	_, err := Parse("status:wontfix")
+	if err == nil {
+		t.Fatal("expected an error for an invalid status, got none")
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	q, err := Parse("status:closed author:Rene label:bug some words")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if q.Status != "closed" {
+		t.Errorf("Status = %q, want %q", q.Status, "closed")
+	}
+	if q.Author != "Rene" {
+		t.Errorf("Author = %q, want %q", q.Author, "Rene")
+	}
+	if q.Label != "bug" {
+		t.Errorf("Label = %q, want %q", q.Label, "bug")
+	}
+	if got, want := q.Title, []string{"some", "words"}; !equalStrings(got, want) {
+		t.Errorf("Title = %v, want %v", got, want)
+	}
+}
+
+func TestParseRepeatedFieldKeepsLast(t *testing.T) {
+	// A second author:/label: field overwrites the first rather than
+	// combining with it -- Query only ever holds one of each.
+	q, err := Parse("author:Rene author:Michael")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if q.Author != "Michael" {
+		t.Errorf("Author = %q, want %q", q.Author, "Michael")
+	}
+}
+
+func TestMatchStatus(t *testing.T) {
+	q, _ := Parse("status:open")
+
+	if !q.Match("open", "", nil, "") {
+		t.Error("expected an open bug to match status:open")
+	}
+	if q.Match("closed", "", nil, "") {
+		t.Error("expected a closed bug not to match status:open")
+	}
+}
+
+func TestMatchAuthorIsCaseInsensitiveSubstring(t *testing.T) {
+	q, _ := Parse("author:rene")
+
+	if !q.Match("", "Rene Descartes", nil, "") {
+		t.Error("expected author match to fold case and allow a substring")
+	}
+	if q.Match("", "Michael", nil, "") {
+		t.Error("expected author mismatch not to match")
+	}
+}
+
+func TestMatchLabelIsCaseInsensitiveExact(t *testing.T) {
+	q, _ := Parse("label:BUG")
+
+	if !q.Match("", "", []string{"bug"}, "") {
+		t.Error("expected label match to fold case")
+	}
+	if q.Match("", "", []string{"bugfix"}, "") {
+		t.Error("label match should be exact, not a substring")
+	}
+}
+
+func TestMatchTitleWordsAreCaseInsensitiveSubstrings(t *testing.T) {
+	q, _ := Parse("Crash Login")
+
+	if !q.Match("", "", nil, "app crashes on login screen") {
+		t.Error("expected every free-text word to match as a substring")
+	}
+	if q.Match("", "", nil, "app crashes on logout screen") {
+		t.Error("expected a missing word to fail the match")
+	}
+}
+
+func TestString(t *testing.T) {
+	q, _ := Parse("status:open author:Rene label:bug crash")
+
+	if got, want := q.String(), "status:open author:Rene label:bug crash"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,11 @@
+// Package repository abstracts over the underlying git storage that bugs
+// are read from and written to.
+package repository
+
+// Repo is a handle on the underlying git repository. It is passed down to
+// the cache and to the editor-backed input helpers, which read and write
+// to it directly.
+type Repo interface {
+	// GetPath returns the path to the repository.
+	GetPath() string
+}
@@ -0,0 +1,110 @@
+package termui
+
+import (
+	"fmt"
+
+	"github.com/MichaelMure/git-bug/cache"
+	"github.com/MichaelMure/git-bug/query"
+	"github.com/jroimartin/gocui"
+)
+
+const bugTableView = "bugTable"
+
+// bugTable is the main window, listing the cached bugs and letting the user
+// narrow them down with a query (see filterPrompt in termui.go).
+type bugTable struct {
+	cache cache.RepoCacher
+
+	filterQuery string
+	bugs        []cache.BugExcerpt
+
+	cursor int
+}
+
+func newBugTable(c cache.RepoCacher) *bugTable {
+	return &bugTable{
+		cache: c,
+		bugs:  c.AllBugs(),
+	}
+}
+
+// Filter returns the query currently applied to the table, in the
+// normalized form produced by query.Query.String.
+func (bt *bugTable) Filter() string {
+	return bt.filterQuery
+}
+
+// SetFilter re-runs the listing against q and replaces the displayed bugs
+// with the result, going through the same cache.RepoCacher.QueryBugs used by
+// the CLI's `git bug ls`.
+func (bt *bugTable) SetFilter(q *query.Query) error {
+	bugs, err := bt.cache.QueryBugs(q)
+	if err != nil {
+		return err
+	}
+
+	bt.filterQuery = q.String()
+	bt.bugs = bugs
+	bt.cursor = 0
+
+	return nil
+}
+
+func (bt *bugTable) keybindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(bugTableView, gocui.KeyArrowUp, gocui.ModNone, bt.cursorUp); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(bugTableView, gocui.KeyArrowDown, gocui.ModNone, bt.cursorDown); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (bt *bugTable) layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	v, err := g.SetView(bugTableView, 0, 0, maxX-1, maxY-1)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	v.Clear()
+	v.Title = "Bugs"
+	if bt.filterQuery != "" {
+		v.Title = fmt.Sprintf("Bugs (filter: %s)", bt.filterQuery)
+	}
+
+	for i, b := range bt.bugs {
+		cursor := "  "
+		if i == bt.cursor {
+			cursor = "> "
+		}
+
+		fmt.Fprintf(v, "%s%s\t%s\t%s\n", cursor, b.Status, b.Author, b.Title)
+	}
+
+	if _, err := g.SetCurrentView(bugTableView); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (bt *bugTable) disable(g *gocui.Gui) error {
+	return g.DeleteView(bugTableView)
+}
+
+func (bt *bugTable) cursorUp(g *gocui.Gui, v *gocui.View) error {
+	if bt.cursor > 0 {
+		bt.cursor--
+	}
+	return nil
+}
+
+func (bt *bugTable) cursorDown(g *gocui.Gui, v *gocui.View) error {
+	if bt.cursor < len(bt.bugs)-1 {
+		bt.cursor++
+	}
+	return nil
+}
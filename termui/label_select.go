@@ -0,0 +1,172 @@
+package termui
+
+import (
+	"fmt"
+
+	"github.com/MichaelMure/git-bug/cache"
+	"github.com/jroimartin/gocui"
+)
+
+const labelSelectView = "labelSelect"
+
+// labelSelect is a popup listing every label known to the cache, letting the
+// user toggle which ones are applied to the bug currently shown in showBug.
+// Like msgPopup and inputPopup, it overlays whatever window is active
+// instead of replacing it, so showBug stays the active window underneath.
+type labelSelect struct {
+	cache cache.RepoCacher
+	bug   cache.BugCacher
+
+	active   bool
+	labels   []string
+	selected map[string]bool
+	cursor   int
+}
+
+func newLabelSelect(cache cache.RepoCacher) *labelSelect {
+	return &labelSelect{cache: cache}
+}
+
+// SetBug resets the popup against a new bug, pre-selecting the labels it
+// already carries, and opens it.
+func (ls *labelSelect) SetBug(bug cache.BugCacher) {
+	ls.bug = bug
+	ls.active = true
+	ls.labels = ls.cache.ValidLabels()
+	ls.selected = make(map[string]bool)
+	ls.cursor = 0
+
+	for _, label := range bug.Snapshot().Labels {
+		ls.selected[label] = true
+	}
+}
+
+func (ls *labelSelect) keybindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding(labelSelectView, gocui.KeyArrowUp, gocui.ModNone, ls.cursorUp); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(labelSelectView, gocui.KeyArrowDown, gocui.ModNone, ls.cursorDown); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(labelSelectView, gocui.KeySpace, gocui.ModNone, ls.toggle); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(labelSelectView, gocui.KeyEnter, gocui.ModNone, ls.apply); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(labelSelectView, gocui.KeyEsc, gocui.ModNone, ls.cancel); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ls *labelSelect) layout(g *gocui.Gui) error {
+	if !ls.active {
+		return nil
+	}
+
+	maxX, maxY := g.Size()
+	width := 40
+	height := minInt(len(ls.labels)+2, maxY-4)
+
+	v, err := g.SetView(labelSelectView, maxX/2-width/2, maxY/2-height/2, maxX/2+width/2, maxY/2+height/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	v.Clear()
+	v.Title = "Labels (space: toggle, enter: apply, esc: cancel)"
+
+	for i, label := range ls.labels {
+		cursor := "  "
+		if i == ls.cursor {
+			cursor = "> "
+		}
+
+		check := "[ ]"
+		if ls.selected[label] {
+			check = "[x]"
+		}
+
+		fmt.Fprintf(v, "%s%s %s\n", cursor, check, label)
+	}
+
+	if _, err := g.SetCurrentView(labelSelectView); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// close hides the popup view and hands focus back to showBug, which stayed
+// the active window the whole time.
+func (ls *labelSelect) close(g *gocui.Gui) error {
+	ls.active = false
+
+	if err := g.DeleteView(labelSelectView); err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	if _, err := g.SetCurrentView("showBug"); err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	return nil
+}
+
+func (ls *labelSelect) cursorUp(g *gocui.Gui, v *gocui.View) error {
+	if ls.cursor > 0 {
+		ls.cursor--
+	}
+	return nil
+}
+
+func (ls *labelSelect) cursorDown(g *gocui.Gui, v *gocui.View) error {
+	if ls.cursor < len(ls.labels)-1 {
+		ls.cursor++
+	}
+	return nil
+}
+
+func (ls *labelSelect) toggle(g *gocui.Gui, v *gocui.View) error {
+	if len(ls.labels) == 0 {
+		return nil
+	}
+
+	label := ls.labels[ls.cursor]
+	ls.selected[label] = !ls.selected[label]
+
+	return nil
+}
+
+// apply diffs the selection against the bug's current labels and pushes the
+// change through cache.BugCacher.ChangeLabels before closing the popup.
+func (ls *labelSelect) apply(g *gocui.Gui, v *gocui.View) error {
+	existing := make(map[string]bool)
+	for _, label := range ls.bug.Snapshot().Labels {
+		existing[label] = true
+	}
+
+	var added, removed []string
+	for _, label := range ls.labels {
+		switch {
+		case ls.selected[label] && !existing[label]:
+			added = append(added, label)
+		case !ls.selected[label] && existing[label]:
+			removed = append(removed, label)
+		}
+	}
+
+	if len(added) > 0 || len(removed) > 0 {
+		if err := ls.bug.ChangeLabels(added, removed); err != nil {
+			ui.msgPopup.Activate(msgPopupErrorTitle, err.Error())
+		}
+	}
+
+	return ls.close(g)
+}
+
+func (ls *labelSelect) cancel(g *gocui.Gui, v *gocui.View) error {
+	return ls.close(g)
+}
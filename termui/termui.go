@@ -1,26 +1,34 @@
 package termui
 
 import (
+	"errors"
+	"os"
+
 	"github.com/MichaelMure/git-bug/cache"
 	"github.com/MichaelMure/git-bug/input"
+	"github.com/MichaelMure/git-bug/query"
 	"github.com/MichaelMure/git-bug/repository"
 	"github.com/jroimartin/gocui"
-	"github.com/pkg/errors"
+	"golang.org/x/term"
 )
 
-var errTerminateMainloop = errors.New("terminate gocui mainloop")
+// errSuspend is returned by a keybinding handler to ask runGui to stop its
+// gocui.Gui so suspendFn can run with exclusive access to the tty.
+var errSuspend = errors.New("suspend termui to run an external command")
 
 type termUI struct {
-	g      *gocui.Gui
-	gError chan error
-	cache  cache.RepoCacher
+	g         *gocui.Gui
+	cache     cache.RepoCacher
+	termState *term.State
+	suspendFn func() error
 
 	activeWindow window
 
-	bugTable   *bugTable
-	showBug    *showBug
-	msgPopup   *msgPopup
-	inputPopup *inputPopup
+	bugTable    *bugTable
+	showBug     *showBug
+	labelSelect *labelSelect
+	msgPopup    *msgPopup
+	inputPopup  *inputPopup
 }
 
 func (tui *termUI) activateWindow(window window) error {
@@ -45,69 +53,105 @@ type window interface {
 func Run(repo repository.Repo) error {
 	c := cache.NewRepoCache(repo)
 
+	state, err := term.GetState(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+
 	ui = &termUI{
-		gError:     make(chan error, 1),
-		cache:      c,
-		bugTable:   newBugTable(c),
-		showBug:    newShowBug(c),
-		msgPopup:   newMsgPopup(),
-		inputPopup: newInputPopup(),
+		cache:       c,
+		termState:   state,
+		bugTable:    newBugTable(c),
+		showBug:     newShowBug(c),
+		labelSelect: newLabelSelect(c),
+		msgPopup:    newMsgPopup(),
+		inputPopup:  newInputPopup(),
 	}
 
 	ui.activeWindow = ui.bugTable
 
-	initGui(nil)
-
-	err := <-ui.gError
-
-	if err != nil && err != gocui.ErrQuit {
-		return err
+	for {
+		quit, err := runGui()
+		if err != nil {
+			return err
+		}
+		if quit {
+			return nil
+		}
 	}
-
-	return nil
 }
 
-func initGui(action func(ui *termUI) error) {
+// runGui creates a fresh gocui.Gui and runs it until it either quits or a
+// keybinding asks to suspend (via requestSuspend below), and reports
+// which one happened. termUI's own state -- the active window, scroll
+// positions, caches -- lives on ui itself, outside the Gui, so recreating
+// the Gui here never loses it.
+func runGui() (quit bool, err error) {
 	g, err := gocui.NewGui(gocui.OutputNormal)
-
 	if err != nil {
-		ui.gError <- err
-		return
+		return false, err
 	}
 
 	ui.g = g
+	defer func() { ui.g = nil }()
 
-	ui.g.SetManagerFunc(layout)
+	g.SetManagerFunc(layout)
 
-	err = keybindings(ui.g)
+	if err := keybindings(g); err != nil {
+		g.Close()
+		return false, err
+	}
 
-	if err != nil {
-		ui.g.Close()
-		ui.g = nil
-		ui.gError <- err
-		return
+	err = g.MainLoop()
+	g.Close()
+
+	switch err {
+	case gocui.ErrQuit:
+		return true, nil
+
+	case errSuspend:
+		fn := ui.suspendFn
+		ui.suspendFn = nil
+		return false, suspend(fn)
+
+	default:
+		return false, err
 	}
+}
 
-	if action != nil {
-		err = action(ui)
-		if err != nil {
-			ui.g.Close()
-			ui.g = nil
-			ui.gError <- err
-			return
-		}
+// suspend gives the terminal back to cooked mode so fn (typically spawning
+// $EDITOR on the controlling tty) can run with exclusive access to it, then
+// restores raw mode. It is only ever called once gocui's own Gui has been
+// closed (see runGui), which closes the underlying termbox fd, so the editor
+// never races gocui for bytes read off the tty. gocui (v0.5.0) has no
+// primitive to pause its polling goroutine short of Close(), and that
+// goroutine can be left blocked forever on its own internal channels once
+// the fd behind it is gone; in practice this leaks one idle goroutine per
+// suspend/resume cycle rather than per keystroke. Whether that's acceptable
+// for a long-lived session with many edits, versus worth forking/patching
+// gocui to add a real pause primitive, is an open question -- needs sign-off
+// from the team, not just this author, before this lands as-is.
+func suspend(fn func() error) error {
+	fd := int(os.Stdin.Fd())
+
+	if err := term.Restore(fd, ui.termState); err != nil {
+		return err
 	}
 
-	err = g.MainLoop()
+	fnErr := fn()
 
-	if err != nil && err != errTerminateMainloop {
-		if ui.g != nil {
-			ui.g.Close()
-		}
-		ui.gError <- err
+	if _, err := term.MakeRaw(fd); err != nil && fnErr == nil {
+		return err
 	}
 
-	return
+	return fnErr
+}
+
+// requestSuspend is called from within a keybinding handler to ask runGui
+// to stop gocui and hand the tty to fn.
+func requestSuspend(fn func() error) error {
+	ui.suspendFn = fn
+	return errSuspend
 }
 
 func layout(g *gocui.Gui) error {
@@ -125,6 +169,10 @@ func layout(g *gocui.Gui) error {
 		return err
 	}
 
+	if err := ui.labelSelect.layout(g); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -138,10 +186,22 @@ func keybindings(g *gocui.Gui) error {
 		return err
 	}
 
+	if err := g.SetKeybinding("bugTable", '/', gocui.ModNone, filterPrompt); err != nil {
+		return err
+	}
+
 	if err := ui.showBug.keybindings(g); err != nil {
 		return err
 	}
 
+	if err := g.SetKeybinding("showBug", 'l', gocui.ModNone, openLabelSelect); err != nil {
+		return err
+	}
+
+	if err := ui.labelSelect.keybindings(g); err != nil {
+		return err
+	}
+
 	if err := ui.msgPopup.keybindings(g); err != nil {
 		return err
 	}
@@ -157,107 +217,105 @@ func quit(g *gocui.Gui, v *gocui.View) error {
 	return gocui.ErrQuit
 }
 
-func newBugWithEditor(repo cache.RepoCacher) error {
-	// This is somewhat hacky.
-	// As there is no way to pause gocui, run the editor and restart gocui,
-	// we have to stop it entirely and start a new one later.
-	//
-	// - an error channel is used to route the returned error of this new
-	// 		instance into the original launch function
-	// - a custom error (errTerminateMainloop) is used to terminate the original
-	//		instance's mainLoop. This error is then filtered.
+// filterPrompt opens the inputPopup to let the user type a query that
+// narrows down the bug table. The query uses the same mini language as the
+// CLI's `git bug ls` (status:, author:, label: and a free-text title match),
+// so the two stay consistent.
+func filterPrompt(g *gocui.Gui, v *gocui.View) error {
+	ui.inputPopup.Activate("Filter ("+query.Usage()+")", ui.bugTable.Filter(), applyFilter)
+	return nil
+}
 
-	ui.g.Close()
-	ui.g = nil
+// openLabelSelect opens the labelSelect popup over showBug, preloaded with
+// the labels of the bug currently displayed there. showBug stays the active
+// window; labelSelect is only ever an overlay, like msgPopup and inputPopup.
+func openLabelSelect(g *gocui.Gui, v *gocui.View) error {
+	ui.labelSelect.SetBug(ui.showBug.Bug())
+	return nil
+}
 
-	title, message, err := input.BugCreateEditorInput(ui.cache.Repository(), "", "")
+// applyFilter parses the raw query and pushes the resulting filter down to
+// the bug table, falling back to the unfiltered listing when the query is
+// empty. Filtering goes through the cache so the result is always in sync
+// with the underlying bug cache, just like the CLI.
+func applyFilter(raw string) error {
+	q, err := query.Parse(raw)
+	if err != nil {
+		ui.msgPopup.Activate(msgPopupErrorTitle, err.Error())
+		return ui.activateWindow(ui.bugTable)
+	}
 
-	if err != nil && err != input.ErrEmptyTitle {
+	if err := ui.bugTable.SetFilter(q); err != nil {
 		return err
 	}
 
-	var b cache.BugCacher
-	if err == input.ErrEmptyTitle {
-		ui.msgPopup.Activate(msgPopupErrorTitle, "Empty title, aborting.")
-	} else {
-		b, err = repo.NewBug(title, message)
+	return ui.activateWindow(ui.bugTable)
+}
+
+func newBugWithEditor(repo cache.RepoCacher) error {
+	return requestSuspend(func() error {
+		title, message, err := input.BugCreateEditorInput(ui.cache.Repository(), "", "")
+
+		if err != nil && err != input.ErrEmptyTitle {
+			return err
+		}
+
+		if err == input.ErrEmptyTitle {
+			ui.msgPopup.Activate(msgPopupErrorTitle, "Empty title, aborting.")
+			return nil
+		}
+
+		b, err := repo.NewBug(title, message)
 		if err != nil {
 			return err
 		}
-	}
+		if b == nil {
+			return nil
+		}
 
-	initGui(func(ui *termUI) error {
+		// The Gui that ran this keybinding is already gone by the time fn
+		// runs (see runGui), so there's no stale view to clean up: just
+		// point at the new active window directly, instead of going
+		// through activateWindow.
 		ui.showBug.SetBug(b)
-		return ui.activateWindow(ui.showBug)
-	})
+		ui.activeWindow = ui.showBug
 
-	return errTerminateMainloop
+		return nil
+	})
 }
 
 func addCommentWithEditor(bug cache.BugCacher) error {
-	// This is somewhat hacky.
-	// As there is no way to pause gocui, run the editor and restart gocui,
-	// we have to stop it entirely and start a new one later.
-	//
-	// - an error channel is used to route the returned error of this new
-	// 		instance into the original launch function
-	// - a custom error (errTerminateMainloop) is used to terminate the original
-	//		instance's mainLoop. This error is then filtered.
-
-	ui.g.Close()
-	ui.g = nil
-
-	message, err := input.BugCommentEditorInput(ui.cache.Repository())
-
-	if err != nil && err != input.ErrEmptyMessage {
-		return err
-	}
+	return requestSuspend(func() error {
+		message, err := input.BugCommentEditorInput(ui.cache.Repository())
 
-	if err == input.ErrEmptyMessage {
-		ui.msgPopup.Activate(msgPopupErrorTitle, "Empty message, aborting.")
-	} else {
-		err := bug.AddComment(message)
-		if err != nil {
+		if err != nil && err != input.ErrEmptyMessage {
 			return err
 		}
-	}
 
-	initGui(nil)
+		if err == input.ErrEmptyMessage {
+			ui.msgPopup.Activate(msgPopupErrorTitle, "Empty message, aborting.")
+			return nil
+		}
 
-	return errTerminateMainloop
+		return bug.AddComment(message)
+	})
 }
 
 func setTitleWithEditor(bug cache.BugCacher) error {
-	// This is somewhat hacky.
-	// As there is no way to pause gocui, run the editor and restart gocui,
-	// we have to stop it entirely and start a new one later.
-	//
-	// - an error channel is used to route the returned error of this new
-	// 		instance into the original launch function
-	// - a custom error (errTerminateMainloop) is used to terminate the original
-	//		instance's mainLoop. This error is then filtered.
-
-	ui.g.Close()
-	ui.g = nil
-
-	title, err := input.BugTitleEditorInput(ui.cache.Repository(), bug.Snapshot().Title)
-
-	if err != nil && err != input.ErrEmptyTitle {
-		return err
-	}
+	return requestSuspend(func() error {
+		title, err := input.BugTitleEditorInput(ui.cache.Repository(), bug.Snapshot().Title)
 
-	if err == input.ErrEmptyTitle {
-		ui.msgPopup.Activate(msgPopupErrorTitle, "Empty title, aborting.")
-	} else {
-		err := bug.SetTitle(title)
-		if err != nil {
+		if err != nil && err != input.ErrEmptyTitle {
 			return err
 		}
-	}
 
-	initGui(nil)
+		if err == input.ErrEmptyTitle {
+			ui.msgPopup.Activate(msgPopupErrorTitle, "Empty title, aborting.")
+			return nil
+		}
 
-	return errTerminateMainloop
+		return bug.SetTitle(title)
+	})
 }
 
 func maxInt(a, b int) int {